@@ -0,0 +1,139 @@
+/*
+ * Copyright 2014 Canonical Ltd.
+ *
+ * Authors:
+ * Sergio Schvezov: sergio.schvezov@cannical.com
+ *
+ * This file is part of mms.
+ *
+ * mms is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; version 3.
+ *
+ * mms is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mms
+
+import "strings"
+
+// AddressKind is the /TYPE= an Address is tagged with on the wire, per
+// OMA-MMS-ENC section 8.1 "Address model".
+type AddressKind int
+
+const (
+	AddressUnknown AddressKind = iota
+	AddressPLMN
+	AddressRFC2822
+	AddressIPv4
+	AddressIPv6
+)
+
+func (k AddressKind) String() string {
+	switch k {
+	case AddressPLMN:
+		return "PLMN"
+	case AddressRFC2822:
+		return "RFC2822"
+	case AddressIPv4:
+		return "IPv4"
+	case AddressIPv6:
+		return "IPv6"
+	default:
+		return "Unknown"
+	}
+}
+
+// Address is a single MMS recipient or sender address: a value plus the
+// Kind it is, or should be, tagged with on the wire.
+type Address struct {
+	Value string
+	Kind  AddressKind
+}
+
+// ParseAddress parses a single wire-format address. If addr already carries
+// an explicit /TYPE= suffix, that is preserved; otherwise the Kind is
+// auto-detected as RFC2822 if addr contains "@", and PLMN otherwise.
+func ParseAddress(addr string) Address {
+	if i := strings.Index(addr, "/TYPE="); i != -1 {
+		kind := parseAddressKind(addr[i+len("/TYPE="):])
+		if kind == AddressUnknown {
+			// Unrecognized type tag (e.g. a short code or a future OMA type
+			// this package doesn't enumerate): keep addr verbatim so Encode
+			// round-trips it unchanged instead of silently dropping the tag.
+			return Address{Value: addr, Kind: AddressUnknown}
+		}
+		return Address{Value: addr[:i], Kind: kind}
+	}
+	if strings.Contains(addr, "@") {
+		return Address{Value: addr, Kind: AddressRFC2822}
+	}
+	return Address{Value: addr, Kind: AddressPLMN}
+}
+
+func parseAddressKind(typ string) AddressKind {
+	switch typ {
+	case "PLMN":
+		return AddressPLMN
+	case "RFC2822":
+		return AddressRFC2822
+	case "IPv4":
+		return AddressIPv4
+	case "IPv6":
+		return AddressIPv6
+	default:
+		return AddressUnknown
+	}
+}
+
+// Encode renders a in wire format. AddressUnknown is rendered bare, since
+// its Value is assumed to already carry its own /TYPE= (or to be a short
+// code that carries none); every other Kind gets an explicit suffix.
+func (a Address) Encode() string {
+	if a.Kind == AddressUnknown {
+		return a.Value
+	}
+	return a.Value + "/TYPE=" + a.Kind.String()
+}
+
+// AddressList is a comma-separated list of addresses, as carried on the
+// wire by the To, Cc and Bcc headers.
+type AddressList []Address
+
+// ParseAddressList splits s on "," and parses each address with
+// ParseAddress.
+func ParseAddressList(s string) AddressList {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	list := make(AddressList, len(parts))
+	for i, part := range parts {
+		list[i] = ParseAddress(strings.TrimSpace(part))
+	}
+	return list
+}
+
+// Encode renders the list in wire format, joining each Address with ",".
+func (l AddressList) Encode() string {
+	if len(l) == 0 {
+		return ""
+	}
+	parts := make([]string, len(l))
+	for i, a := range l {
+		parts[i] = a.Encode()
+	}
+	return strings.Join(parts, ",")
+}
+
+// String returns the same comma-joined wire format as Encode, so
+// AddressList satisfies fmt.Stringer for logging.
+func (l AddressList) String() string {
+	return l.Encode()
+}
@@ -0,0 +1,246 @@
+/*
+ * Copyright 2014 Canonical Ltd.
+ *
+ * Authors:
+ * Sergio Schvezov: sergio.schvezov@cannical.com
+ *
+ * This file is part of mms.
+ *
+ * mms is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; version 3.
+ *
+ * mms is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mm7
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+
+	"github.com/doflah/nuntium/mms"
+)
+
+// soapMultipart wraps a SOAP envelope and the message's media parts in a
+// multipart/related body, per OMA-MM7 section 11.2.
+func soapMultipart(envelope []byte, attachments []attachmentPart) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	root, err := writer.CreatePart(map[string][]string{
+		"Content-Type": {`text/xml; charset="utf-8"`},
+		"Content-ID":   {"<soap-envelope>"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := root.Write(envelope); err != nil {
+		return nil, "", err
+	}
+
+	for _, a := range attachments {
+		part, err := writer.CreatePart(map[string][]string{
+			"Content-Type": {a.ContentType},
+			"Content-ID":   {fmt.Sprintf("<%s>", a.ContentID)},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(a.Data); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	contentType := fmt.Sprintf(`multipart/related; type="text/xml"; start="<soap-envelope>"; boundary=%s`, writer.Boundary())
+	return buf.Bytes(), contentType, nil
+}
+
+// attachmentPart is the wire form of a mms.Attachment once its file content
+// has been read off disk for inclusion in the multipart/related body.
+type attachmentPart struct {
+	ContentID   string
+	ContentType string
+	Data        []byte
+}
+
+func readAttachments(attachments []*mms.Attachment) ([]attachmentPart, error) {
+	parts := make([]attachmentPart, 0, len(attachments))
+	for i, a := range attachments {
+		data, err := os.ReadFile(a.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("mm7: reading attachment %d (%s): %w", i, a.FilePath, err)
+		}
+		parts = append(parts, attachmentPart{
+			ContentID:   fmt.Sprintf("part%d", i),
+			ContentType: a.ContentType,
+			Data:        data,
+		})
+	}
+	return parts, nil
+}
+
+// wrapSOAPEnvelope wraps an already-marshaled PDU (whose own XMLName gives
+// it its element name in the mm7NS namespace) in a SOAP 1.1 Envelope/Body,
+// per OMA-MM7 section 11.1.
+func wrapSOAPEnvelope(body []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(&buf, `<SOAP-ENV:Envelope xmlns:SOAP-ENV=%q><SOAP-ENV:Body>`, soapEnvelopeNS)
+	buf.Write(body)
+	buf.WriteString(`</SOAP-ENV:Body></SOAP-ENV:Envelope>`)
+	return buf.Bytes()
+}
+
+// unwrapSOAPBody returns the raw XML of the SOAP Body's single child
+// element, so callers can unmarshal it as the specific PDU type they
+// expect rather than the generic envelope.
+func unwrapSOAPBody(data []byte) ([]byte, error) {
+	var env soapEnvelope
+	if err := xml.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	return env.Body.Contents, nil
+}
+
+func encodeSubmitReq(req *SubmitReq) ([]byte, string, error) {
+	req.XMLName = xml.Name{Space: mm7NS, Local: "SubmitReq"}
+	parts, err := readAttachments(req.Attachments)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Content = contentRefs(parts)
+	body, err := xml.Marshal(req)
+	if err != nil {
+		return nil, "", err
+	}
+	return soapMultipart(wrapSOAPEnvelope(body), parts)
+}
+
+func encodeCancelReq(req *CancelReq) ([]byte, string, error) {
+	req.XMLName = xml.Name{Space: mm7NS, Local: "CancelReq"}
+	body, err := xml.Marshal(req)
+	if err != nil {
+		return nil, "", err
+	}
+	return soapMultipart(wrapSOAPEnvelope(body), nil)
+}
+
+func encodeReplaceReq(req *ReplaceReq) ([]byte, string, error) {
+	req.XMLName = xml.Name{Space: mm7NS, Local: "ReplaceReq"}
+	parts, err := readAttachments(req.SubmitReq.Attachments)
+	if err != nil {
+		return nil, "", err
+	}
+	req.SubmitReq.Content = contentRefs(parts)
+	body, err := xml.Marshal(req)
+	if err != nil {
+		return nil, "", err
+	}
+	return soapMultipart(wrapSOAPEnvelope(body), parts)
+}
+
+// contentRefs builds the Content element list pointing at each attachment
+// part by its multipart Content-ID, so the SOAP body references media by
+// cid instead of marshaling the attachment (and its local FilePath) inline.
+func contentRefs(parts []attachmentPart) []ContentRef {
+	if len(parts) == 0 {
+		return nil
+	}
+	refs := make([]ContentRef, len(parts))
+	for i, p := range parts {
+		refs[i] = ContentRef{Href: "cid:" + p.ContentID}
+	}
+	return refs
+}
+
+func decodeSubmitRsp(r io.Reader) (*SubmitRsp, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := unwrapSOAPBody(data)
+	if err != nil {
+		return nil, err
+	}
+	var rsp SubmitRsp
+	if err := xml.Unmarshal(inner, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func decodeCancelRsp(r io.Reader) (*CancelRsp, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := unwrapSOAPBody(data)
+	if err != nil {
+		return nil, err
+	}
+	var rsp CancelRsp
+	if err := xml.Unmarshal(inner, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func decodeReplaceRsp(r io.Reader) (*ReplaceRsp, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := unwrapSOAPBody(data)
+	if err != nil {
+		return nil, err
+	}
+	var rsp ReplaceRsp
+	if err := xml.Unmarshal(inner, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// decodeDeliverReq unmarshals the SOAP Body's inner XML (as returned by
+// unwrapSOAPBody) into a DeliverReq.
+func decodeDeliverReq(inner []byte) (*DeliverReq, error) {
+	var req DeliverReq
+	if err := xml.Unmarshal(inner, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// decodeDeliveryReportReq unmarshals the SOAP Body's inner XML (as returned
+// by unwrapSOAPBody) into a DeliveryReportReq.
+func decodeDeliveryReportReq(inner []byte) (*DeliveryReportReq, error) {
+	var req DeliveryReportReq
+	if err := xml.Unmarshal(inner, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// decodeReadReplyReq unmarshals the SOAP Body's inner XML (as returned by
+// unwrapSOAPBody) into a ReadReplyReq.
+func decodeReadReplyReq(inner []byte) (*ReadReplyReq, error) {
+	var req ReadReplyReq
+	if err := xml.Unmarshal(inner, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
@@ -0,0 +1,426 @@
+/*
+ * Copyright 2014 Canonical Ltd.
+ *
+ * Authors:
+ * Sergio Schvezov: sergio.schvezov@cannical.com
+ *
+ * This file is part of mms.
+ *
+ * mms is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; version 3.
+ *
+ * mms is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package mm7 implements the MM7 SOAP interface (3GPP TS 23.140 / OMA-MM7)
+// between a VASP and an MMS Relay/Server, as a sibling to the MM1/WSP codec
+// in the parent mms package.
+package mm7
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/doflah/nuntium/mms"
+)
+
+// MM7 version strings carried in each request's MM7Version element, per
+// OMA-MM7 section 9.
+const (
+	Version5_3_0 = "5.3.0"
+	Version6_8_0 = "6.8.0"
+)
+
+// SOAP 1.1 envelope constants used to build and parse MM7 requests.
+const (
+	soapEnvelopeNS = "http://schemas.xmlsoap.org/soap/envelope/"
+	mm7NS          = "http://www.3gpp.org/ftp/specs/archive/23_series/23.140/schema/REL-5-MM7-1-4"
+)
+
+// SubmitReq is the VASP -> MMSC SubmitReq, used to submit a new message for
+// delivery to one or more recipients.
+type SubmitReq struct {
+	XMLName       xml.Name
+	MM7Version    string
+	TransactionId string
+	VASPID        string
+	VASID         string
+	SenderAddress string
+	Recipients    []string
+	Subject       string
+	ContentType   string
+	Content       []ContentRef      `xml:"Content"`
+	Attachments   []*mms.Attachment `xml:"-"`
+}
+
+// ContentRef points, by Content-ID, at a media part carried alongside the
+// SOAP envelope in the multipart/related body, per OMA-MM7 section 11.2.
+// Attachments are never marshaled inline into the envelope: their bytes
+// (and local FilePath) have no business on the wire twice, once here and
+// once as their own multipart part.
+type ContentRef struct {
+	Href string `xml:"href,attr"`
+}
+
+// SubmitRsp is the MMSC's response to a SubmitReq.
+type SubmitRsp struct {
+	XMLName       xml.Name
+	TransactionId string
+	MessageId     string
+	StatusCode    string
+	StatusText    string
+}
+
+// DeliverReq is the MMSC -> VASP notification that a message has arrived
+// for one of the VASP's addresses.
+type DeliverReq struct {
+	XMLName       xml.Name
+	MM7Version    string
+	TransactionId string
+	MessageId     string
+	SenderAddress string
+	Recipients    []string
+	Subject       string
+	ContentType   string
+	Content       []ContentRef      `xml:"Content"`
+	Attachments   []*mms.Attachment `xml:"-"`
+}
+
+// DeliverRsp is the VASP's acknowledgement of a DeliverReq.
+type DeliverRsp struct {
+	XMLName       xml.Name
+	TransactionId string
+	StatusCode    string
+	StatusText    string
+}
+
+// CancelReq asks the MMSC to cancel a previously submitted message that has
+// not yet been delivered.
+type CancelReq struct {
+	XMLName       xml.Name
+	MM7Version    string
+	TransactionId string
+	VASPID        string
+	VASID         string
+	MessageId     string
+}
+
+// CancelRsp is the MMSC's response to a CancelReq.
+type CancelRsp struct {
+	XMLName       xml.Name
+	TransactionId string
+	StatusCode    string
+	StatusText    string
+}
+
+// ReplaceReq asks the MMSC to replace a previously submitted, not yet
+// delivered message with a new one.
+type ReplaceReq struct {
+	XMLName       xml.Name
+	MM7Version    string
+	TransactionId string
+	VASPID        string
+	VASID         string
+	MessageId     string
+	SubmitReq     SubmitReq
+}
+
+// ReplaceRsp is the MMSC's response to a ReplaceReq.
+type ReplaceRsp struct {
+	XMLName       xml.Name
+	TransactionId string
+	StatusCode    string
+	StatusText    string
+}
+
+// DeliveryReportReq is the MMSC -> VASP delivery report for a message
+// previously submitted with a SubmitReq.
+type DeliveryReportReq struct {
+	XMLName       xml.Name
+	MM7Version    string
+	TransactionId string
+	MessageId     string
+	Recipient     string
+	Date          string
+	MMStatus      string
+	StatusText    string
+}
+
+// ReadReplyReq is the MMSC -> VASP read-reply report for a message
+// previously submitted with a SubmitReq.
+type ReadReplyReq struct {
+	XMLName       xml.Name
+	MM7Version    string
+	TransactionId string
+	MessageId     string
+	Recipient     string
+	Date          string
+	ReadStatus    string
+}
+
+// MM7Client submits and manages messages against a single MMSC's MM7
+// endpoint on behalf of a VASP.
+type MM7Client struct {
+	Endpoint string
+	VASPID   string
+	VASID    string
+	Username string
+	Password string
+	Version  string
+
+	httpClient *http.Client
+}
+
+// NewMM7Client returns a MM7Client targeting endpoint, identifying itself
+// with vaspID/vasID and authenticating with HTTP basic auth. version should
+// be Version5_3_0 or Version6_8_0.
+func NewMM7Client(endpoint, vaspID, vasID, username, password, version string) *MM7Client {
+	return &MM7Client{
+		Endpoint:   endpoint,
+		VASPID:     vaspID,
+		VASID:      vasID,
+		Username:   username,
+		Password:   password,
+		Version:    version,
+		httpClient: &http.Client{},
+	}
+}
+
+// Submit posts req to the MMSC as a SOAP-over-HTTP SubmitReq and returns the
+// decoded SubmitRsp.
+func (c *MM7Client) Submit(req *SubmitReq) (*SubmitRsp, error) {
+	req.VASPID = c.VASPID
+	req.VASID = c.VASID
+	req.MM7Version = c.Version
+	body, contentType, err := encodeSubmitReq(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.post(body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return decodeSubmitRsp(resp.Body)
+}
+
+// Cancel posts a CancelReq for messageId to the MMSC.
+func (c *MM7Client) Cancel(messageId string) (*CancelRsp, error) {
+	req := &CancelReq{VASPID: c.VASPID, VASID: c.VASID, MessageId: messageId, MM7Version: c.Version}
+	body, contentType, err := encodeCancelReq(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.post(body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return decodeCancelRsp(resp.Body)
+}
+
+// Replace posts a ReplaceReq substituting messageId with req.
+func (c *MM7Client) Replace(messageId string, req *SubmitReq) (*ReplaceRsp, error) {
+	replaceReq := &ReplaceReq{VASPID: c.VASPID, VASID: c.VASID, MessageId: messageId, SubmitReq: *req, MM7Version: c.Version}
+	body, contentType, err := encodeReplaceReq(replaceReq)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.post(body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return decodeReplaceRsp(resp.Body)
+}
+
+func (c *MM7Client) post(body []byte, contentType string) (*http.Response, error) {
+	httpReq, err := http.NewRequest("POST", c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("SOAPAction", "")
+	if c.Username != "" {
+		httpReq.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("mm7: MMSC returned HTTP %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// Handler is implemented by callers of MM7Server to react to inbound
+// DeliverReq, DeliveryReportReq and ReadReplyReq envelopes.
+type Handler interface {
+	HandleDeliver(req *DeliverReq) (*DeliverRsp, error)
+	HandleDeliveryReport(req *DeliveryReportReq) error
+	HandleReadReply(req *ReadReplyReq) error
+}
+
+// MM7Server decodes inbound MM7 SOAP envelopes from a MMSC and dispatches
+// them to a Handler. It implements http.Handler.
+type MM7Server struct {
+	Handler Handler
+}
+
+// NewMM7Server returns a MM7Server dispatching to handler.
+func NewMM7Server(handler Handler) *MM7Server {
+	return &MM7Server{Handler: handler}
+}
+
+func (s *MM7Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var envelope []byte
+	if mediaType == "multipart/related" {
+		envelope, err = firstMultipartPart(r.Body, params["boundary"])
+	} else {
+		envelope, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	kind, body, err := envelopeKind(envelope)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch kind {
+	case "DeliverReq":
+		req, err := decodeDeliverReq(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := checkMM7Version(req.MM7Version); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rsp, err := s.Handler.HandleDeliver(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rsp.XMLName = xml.Name{Space: mm7NS, Local: "DeliverRsp"}
+		writeSOAPResponse(w, rsp)
+	case "DeliveryReportReq":
+		req, err := decodeDeliveryReportReq(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := checkMM7Version(req.MM7Version); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Handler.HandleDeliveryReport(req); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case "ReadReplyReq":
+		req, err := decodeReadReplyReq(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := checkMM7Version(req.MM7Version); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Handler.HandleReadReply(req); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, fmt.Sprintf("mm7: unsupported SOAP body %q", kind), http.StatusBadRequest)
+	}
+}
+
+// checkMM7Version rejects envelopes negotiating a version this package
+// doesn't implement, per OMA-MM7 section 9's version negotiation.
+func checkMM7Version(version string) error {
+	switch version {
+	case Version5_3_0, Version6_8_0:
+		return nil
+	default:
+		return fmt.Errorf("mm7: unsupported MM7Version %q", version)
+	}
+}
+
+// soapEnvelope is the generic SOAP 1.1 envelope shape used for decoding
+// inbound envelopes (outbound ones are built by wrapSOAPEnvelope); Body is
+// kept as raw XML so each PDU can define its own element.
+type soapEnvelope struct {
+	XMLName xml.Name `xml:"http://schemas.xmlsoap.org/soap/envelope/ Envelope"`
+	Body    struct {
+		Contents []byte `xml:",innerxml"`
+	} `xml:"http://schemas.xmlsoap.org/soap/envelope/ Body"`
+}
+
+// envelopeKind parses the SOAP envelope in data and returns the local name
+// of its Body's single child element along with that element's raw XML, so
+// the caller can unmarshal it as the specific PDU type it names.
+func envelopeKind(data []byte) (string, []byte, error) {
+	var env soapEnvelope
+	if err := xml.Unmarshal(data, &env); err != nil {
+		return "", nil, err
+	}
+	var probe struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(env.Body.Contents, &probe); err != nil {
+		return "", nil, err
+	}
+	return probe.XMLName.Local, env.Body.Contents, nil
+}
+
+func firstMultipartPart(r io.Reader, boundary string) ([]byte, error) {
+	if boundary == "" {
+		return nil, errors.New("mm7: multipart/related request missing boundary")
+	}
+	reader := multipart.NewReader(r, boundary)
+	part, err := reader.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(part)
+}
+
+func writeSOAPResponse(w http.ResponseWriter, rsp interface{}) {
+	body, err := xml.Marshal(rsp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(wrapSOAPEnvelope(body))
+}
@@ -22,10 +22,11 @@
 package mms
 
 import (
+	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
-	"os"
-	"strings"
+	"io"
 )
 
 // MMS Field names from OMA-WAP-MMS section 7.3 Table 12
@@ -65,6 +66,40 @@ const (
 	X_MMS_PREVIOUSLY_SENT_DATE    = 0x21
 )
 
+// MMS Field names added by OMA-MMS-ENC-V1.3 section 7.3 Table 12 for the
+// Read-Report, Cancel, Mbox and forward PDUs.
+const (
+	ADDITIONAL_HEADERS               = 0x22
+	CONTENT                          = 0x23
+	X_MMS_STORE                      = 0x24
+	X_MMS_MM_STATE                   = 0x25
+	X_MMS_MM_FLAGS                   = 0x26
+	X_MMS_STORE_STATUS               = 0x27
+	X_MMS_STORE_STATUS_TEXT          = 0x28
+	X_MMS_STORED                     = 0x29
+	X_MMS_ATTRIBUTES                 = 0x2A
+	X_MMS_TOTALS                     = 0x2B
+	X_MMS_MBOX_TOTALS                = 0x2C
+	X_MMS_QUOTAS                     = 0x2D
+	X_MMS_MBOX_QUOTAS                = 0x2E
+	X_MMS_MESSAGE_COUNT              = 0x2F
+	X_MMS_START                      = 0x30
+	X_MMS_DISTRIBUTION_INDICATOR     = 0x31
+	X_MMS_ELEMENT_DESCRIPTOR         = 0x32
+	X_MMS_LIMIT                      = 0x33
+	X_MMS_RECOMMENDED_RETRIEVAL_MODE = 0x34
+	X_MMS_STATUS_TEXT                = 0x35
+	X_MMS_APPLIC_ID                  = 0x36
+	X_MMS_REPLY_APPLIC_ID            = 0x37
+	X_MMS_AUX_APPLIC_INFO            = 0x38
+	X_MMS_CONTENT_CLASS              = 0x39
+	X_MMS_DRM_CONTENT                = 0x3A
+	X_MMS_ADAPTATION_ALLOWED         = 0x3B
+	X_MMS_REPLACE_ID                 = 0x3C
+	X_MMS_CANCEL_ID                  = 0x3D
+	X_MMS_CANCEL_STATUS              = 0x3E
+)
+
 // MMS Content Type Assignments OMA-WAP-MMS section 7.3 Table 13
 const (
 	PUSH_APPLICATION_ID = 4
@@ -81,6 +116,27 @@ const (
 	TYPE_DELIVERY_IND     = 0x86
 )
 
+// MMS PDU types added by OMA-MMS-ENC-V1.3 section 7.1 Table 11 for the
+// Read-Report, Cancel, Mbox and forward message classes.
+const (
+	TYPE_READ_REC_IND     = 0x87
+	TYPE_READ_ORIG_IND    = 0x88
+	TYPE_FORWARD_REQ      = 0x89
+	TYPE_FORWARD_CONF     = 0x8A
+	TYPE_MBOX_STORE_REQ   = 0x8B
+	TYPE_MBOX_STORE_CONF  = 0x8C
+	TYPE_MBOX_VIEW_REQ    = 0x8D
+	TYPE_MBOX_VIEW_CONF   = 0x8E
+	TYPE_MBOX_UPLOAD_REQ  = 0x8F
+	TYPE_MBOX_UPLOAD_CONF = 0x90
+	TYPE_MBOX_DELETE_REQ  = 0x91
+	TYPE_MBOX_DELETE_CONF = 0x92
+	TYPE_DELETE_REQ       = 0x93
+	TYPE_DELETE_CONF      = 0x94
+	TYPE_CANCEL_REQ       = 0x95
+	TYPE_CANCEL_CONF      = 0x96
+)
+
 const (
 	MMS_MESSAGE_VERSION_1_0 = 0x90
 	MMS_MESSAGE_VERSION_1_1 = 0x91
@@ -157,6 +213,27 @@ const (
 	ResponseStatusErrorPermamentMaxReserved byte = 255
 )
 
+// Retrieve Status defined in OMA-WAP-MMS section 7.2.24. Its transient and
+// permanent ranges sit at the same byte values as Response Status's, but
+// the codes they enumerate are not the same field: e.g. 193 here is
+// Error-transient-message-not-found, not Error-transient-address-unresolved.
+const (
+	RetrieveStatusOk byte = 128
+
+	RetrieveStatusErrorTransientFailure         byte = 192
+	RetrieveStatusErrorTransientMessageNotFound byte = 193
+	RetrieveStatusErrorTransientNetworkProblem  byte = 194
+
+	RetrieveStatusErrorTransientMaxReserved byte = 223
+
+	RetrieveStatusErrorPermanentFailure            byte = 224
+	RetrieveStatusErrorPermanentServiceDenied      byte = 225
+	RetrieveStatusErrorPermanentMessageNotFound    byte = 226
+	RetrieveStatusErrorPermanentContentNotAccepted byte = 227
+
+	RetrieveStatusErrorPermamentMaxReserved byte = 255
+)
+
 // Status defined in OMA-WAP-MMS section 7.2.23
 const (
 	STATUS_EXPIRED      = 128
@@ -175,17 +252,17 @@ type MSendReq struct {
 	Version          byte
 	Date             uint64 `encode:"optional"`
 	From             string
-	To               string
-	Cc               string `encode:"no"`
-	Bcc              string `encode:"no"`
-	Subject          string `encode:"optional"`
-	Class            byte   `encode:"optional"`
-	Expiry           uint64 `encode:"optional"`
-	DeliveryTime     uint64 `encode:"optional"`
-	Priority         byte   `encode:"optional"`
-	SenderVisibility byte   `encode:"optional"`
-	DeliveryReport   byte   `encode:"optional"`
-	ReadReply        byte   `encode:"optional"`
+	To               AddressList
+	Cc               AddressList `encode:"optional"`
+	Bcc              AddressList `encode:"optional"`
+	Subject          string      `encode:"optional"`
+	Class            byte        `encode:"optional"`
+	Expiry           uint64      `encode:"optional"`
+	DeliveryTime     uint64      `encode:"optional"`
+	Priority         byte        `encode:"optional"`
+	SenderVisibility byte        `encode:"optional"`
+	DeliveryReport   byte        `encode:"optional"`
+	ReadReply        byte        `encode:"optional"`
 	ContentType      string
 	Attachments      []*Attachment `encode:"no"`
 }
@@ -225,42 +302,293 @@ type MNotifyRespInd struct {
 	ReportAllowed bool
 }
 
+// MAcknowledgeInd holds a m-acknowledge.ind message defined in
+// OMA-WAP-MMS-ENC-v1.1 section 6.4
+type MAcknowledgeInd struct {
+	UUID          string `encode:"no"`
+	Type          byte
+	TransactionId string
+	Version       byte
+	ReportAllowed byte `encode:"optional"`
+}
+
+// MDeliveryInd holds a m-delivery.ind message defined in
+// OMA-WAP-MMS-ENC-v1.1 section 6.5
+type MDeliveryInd struct {
+	UUID      string `encode:"no"`
+	Type      byte
+	MessageId string
+	To        string
+	Date      uint64
+	Status    byte
+}
+
 // MRetrieveConf holds a m-retrieve.conf message defined in
 // OMA-WAP-MMS-ENC-v1.1 section 6.3
 type MRetrieveConf struct {
 	MMSReader
-	UUID                                       string
-	Type, Version, Status, Class, Priority     byte
-	ReplyCharging, ReplyChargingDeadline       byte
-	ReplyChargingId                            string
-	ReadReport, RetrieveStatus, DeliveryReport byte
-	TransactionId, MessageId, RetrieveText     string
-	From, Cc, Subject                          string
-	To                                         string
-	ReportAllowed                              bool
-	Date                                       uint64
-	Content                                    Attachment
-	Attachments                                []Attachment
-	Data                                       []byte
+	UUID                                           string
+	Type, Version, DeliveryStatus, Class, Priority byte
+	ReplyCharging, ReplyChargingDeadline           byte
+	ReplyChargingId                                string
+	ReadReport, RetrieveStatus, DeliveryReport     byte
+	TransactionId, MessageId, RetrieveText         string
+	From, Cc, Subject                              string
+	To                                             string
+	ReportAllowed                                  bool
+	Date                                           uint64
+	Content                                        Attachment
+	Attachments                                    []Attachment
+	Data                                           []byte
+}
+
+// MReadRecInd holds a m-read-rec.ind message defined in
+// OMA-MMS-ENC-V1.3 section 6.5
+type MReadRecInd struct {
+	Type          byte
+	Version       byte
+	MessageId     string
+	To            string
+	From          string
+	Date          uint64 `encode:"optional"`
+	ReadStatus    byte
+	ApplicId      string `encode:"optional"`
+	ReplyApplicId string `encode:"optional"`
+	AuxApplicInfo string `encode:"optional"`
+}
+
+// MReadOrigInd holds a m-read-orig.ind message defined in
+// OMA-MMS-ENC-V1.3 section 6.6
+type MReadOrigInd struct {
+	Type          byte
+	Version       byte
+	MessageId     string
+	To            string
+	From          string
+	Date          uint64 `encode:"optional"`
+	ReadStatus    byte
+	ApplicId      string `encode:"optional"`
+	ReplyApplicId string `encode:"optional"`
+	AuxApplicInfo string `encode:"optional"`
+}
+
+// MForwardReq holds a m-forward.req message defined in
+// OMA-MMS-ENC-V1.3 section 6.7
+type MForwardReq struct {
+	UUID           string `encode:"no"`
+	Type           byte
+	TransactionId  string
+	Version        byte
+	MessageId      string
+	To             AddressList
+	Cc             AddressList `encode:"optional"`
+	Bcc            AddressList `encode:"optional"`
+	DeliveryReport byte        `encode:"optional"`
+	ReadReport     byte        `encode:"optional"`
+	ApplicId       string      `encode:"optional"`
+	ReplyApplicId  string      `encode:"optional"`
+	AuxApplicInfo  string      `encode:"optional"`
+}
+
+// MForwardConf holds a m-forward.conf message defined in
+// OMA-MMS-ENC-V1.3 section 6.7
+type MForwardConf struct {
+	Type           byte
+	TransactionId  string
+	Version        byte
+	ResponseStatus byte
+	ResponseText   string `encode:"optional"`
+	MessageId      string
+}
+
+// MMboxStoreReq holds a m-mbox-store.req message defined in
+// OMA-MMS-ENC-V1.3 section 6.9
+type MMboxStoreReq struct {
+	Type            byte
+	TransactionId   string
+	Version         byte
+	ContentLocation string
+	Store           byte   `encode:"optional"`
+	StatusText      string `encode:"optional"`
+}
+
+// MMboxStoreConf holds a m-mbox-store.conf message defined in
+// OMA-MMS-ENC-V1.3 section 6.9
+type MMboxStoreConf struct {
+	Type            byte
+	TransactionId   string
+	Version         byte
+	StoreStatus     byte
+	StoreStatusText string `encode:"optional"`
+}
+
+// MMboxViewReq holds a m-mbox-view.req message defined in
+// OMA-MMS-ENC-V1.3 section 6.10
+type MMboxViewReq struct {
+	Type          byte
+	TransactionId string
+	Version       byte
+	Start         uint64 `encode:"optional"`
+	Limit         uint64 `encode:"optional"`
+}
+
+// MMboxViewConf holds a m-mbox-view.conf message defined in
+// OMA-MMS-ENC-V1.3 section 6.10
+type MMboxViewConf struct {
+	Type           byte
+	TransactionId  string
+	Version        byte
+	ResponseStatus byte
+	ResponseText   string `encode:"optional"`
+	MessageCount   uint64
+	Totals         uint64       `encode:"optional"`
+	MboxTotals     uint64       `encode:"optional"`
+	Quotas         uint64       `encode:"optional"`
+	MboxQuotas     uint64       `encode:"optional"`
+	Elements       []Attachment `encode:"no"`
+}
+
+// MMboxUploadReq holds a m-mbox-upload.req message defined in
+// OMA-MMS-ENC-V1.3 section 6.11
+type MMboxUploadReq struct {
+	Type          byte
+	TransactionId string
+	Version       byte
+	ContentType   string
+	Attachments   []*Attachment `encode:"no"`
+}
+
+// MMboxUploadConf holds a m-mbox-upload.conf message defined in
+// OMA-MMS-ENC-V1.3 section 6.11
+type MMboxUploadConf struct {
+	Type           byte
+	TransactionId  string
+	Version        byte
+	ResponseStatus byte
+	ResponseText   string `encode:"optional"`
+}
+
+// MMboxDeleteReq holds a m-mbox-delete.req message defined in
+// OMA-MMS-ENC-V1.3 section 6.12
+type MMboxDeleteReq struct {
+	Type            byte
+	TransactionId   string
+	Version         byte
+	ContentLocation []string
+}
+
+// MMboxDeleteConf holds a m-mbox-delete.conf message defined in
+// OMA-MMS-ENC-V1.3 section 6.12
+type MMboxDeleteConf struct {
+	Type           byte
+	TransactionId  string
+	Version        byte
+	ResponseStatus byte
+	ResponseText   string `encode:"optional"`
+	Status         []byte
+	StatusText     []string `encode:"optional"`
+}
+
+// MDeleteReq holds a m-delete.req message defined in
+// OMA-MMS-ENC-V1.3 section 6.13
+type MDeleteReq struct {
+	Type            byte
+	TransactionId   string
+	Version         byte
+	ContentLocation []string
+}
+
+// MDeleteConf holds a m-delete.conf message defined in
+// OMA-MMS-ENC-V1.3 section 6.13
+type MDeleteConf struct {
+	Type           byte
+	TransactionId  string
+	Version        byte
+	ResponseStatus byte
+	ResponseText   string `encode:"optional"`
+	Status         []byte
+	StatusText     []string `encode:"optional"`
+}
+
+// MCancelReq holds a m-cancel.req message defined in
+// OMA-MMS-ENC-V1.3 section 6.14
+type MCancelReq struct {
+	UUID          string `encode:"no"`
+	Type          byte
+	TransactionId string
+	Version       byte
+	CancelId      string
+}
+
+// MCancelConf holds a m-cancel.conf message defined in
+// OMA-MMS-ENC-V1.3 section 6.14
+type MCancelConf struct {
+	Type          byte
+	TransactionId string
+	Version       byte
+	CancelStatus  byte
 }
 
 type MMSReader interface{}
 type MMSWriter interface{}
 
-func NewMSendReq(recipients []string, attachments []*Attachment) *MSendReq {
-	for i := range recipients {
-		recipients[i] += "/TYPE=PLMN"
+// NewMSendReq builds a m-send.req for recipients/cc/bcc.
+//
+// Deprecated: use NewMSendReqContext, which surfaces a RandSource failure
+// as an error instead of panicking.
+func NewMSendReq(recipients, cc, bcc []string, attachments []*Attachment) *MSendReq {
+	req, err := NewMSendReqContext(context.Background(), recipients, cc, bcc, attachments)
+	if err != nil {
+		// NewMSendReq predates NewMSendReqContext's error return and has no
+		// way to surface this to its caller; rather than send a PDU with an
+		// empty, MMSC-colliding TransactionId, fail loudly.
+		panic(err)
+	}
+	return req
+}
+
+// NewMSendReqContext builds a m-send.req addressed to recipients, cc and
+// bcc, returning an error if a transaction id cannot be generated from
+// RandSource. ctx is accepted for future cancellation/tracing use and is
+// not otherwise consulted.
+//
+// Each address is auto-detected as PLMN or RFC2822 by scanning for "@",
+// unless it already carries an explicit /TYPE= suffix, which is preserved.
+func NewMSendReqContext(ctx context.Context, recipients, cc, bcc []string, attachments []*Attachment) (*MSendReq, error) {
+	uuid, err := newUUID()
+	if err != nil {
+		return nil, fmt.Errorf("mms: generating transaction id: %w", err)
 	}
-	uuid := genUUID()
 	return &MSendReq{
 		Type:          TYPE_SEND_REQ,
-		To:            strings.Join(recipients, ","),
+		To:            parseAddresses(recipients),
+		Cc:            parseAddresses(cc),
+		Bcc:           parseAddresses(bcc),
 		TransactionId: uuid,
 		Version:       MMS_MESSAGE_VERSION_1_3,
 		UUID:          uuid,
 		ContentType:   "application/vnd.wap.multipart.related",
 		Attachments:   attachments,
+	}, nil
+}
+
+// parseAddresses parses each raw address in addrs, auto-detecting its Kind.
+func parseAddresses(addrs []string) AddressList {
+	if len(addrs) == 0 {
+		return nil
+	}
+	list := make(AddressList, len(addrs))
+	for i, addr := range addrs {
+		list[i] = ParseAddress(addr)
 	}
+	return list
+}
+
+// ToString returns the wire-format, comma-joined form of To.
+//
+// Deprecated: use the To field (an AddressList) directly.
+func (mSendReq *MSendReq) ToString() string {
+	return mSendReq.To.Encode()
 }
 
 func NewMSendConf() *MSendConf {
@@ -269,8 +597,16 @@ func NewMSendConf() *MSendConf {
 	}
 }
 
+// NewMNotificationInd builds an empty m-notification.ind with a fresh UUID.
+//
+// Deprecated: panics on a RandSource failure, like NewMSendReq; new code
+// should generate a UUID with newUUID and handle the error directly.
 func NewMNotificationInd() *MNotificationInd {
-	return &MNotificationInd{Type: TYPE_NOTIFICATION_IND, UUID: genUUID()}
+	uuid, err := newUUID()
+	if err != nil {
+		panic(err)
+	}
+	return &MNotificationInd{Type: TYPE_NOTIFICATION_IND, UUID: uuid}
 }
 
 func (mNotificationInd *MNotificationInd) NewMNotifyRespInd(status byte, deliveryReport bool) *MNotifyRespInd {
@@ -295,6 +631,23 @@ func (mRetrieveConf *MRetrieveConf) NewMNotifyRespInd(deliveryReport bool) *MNot
 	}
 }
 
+// NewMAcknowledgeInd builds a m-acknowledge.ind acknowledging this
+// retrieve, optionally requesting a delivery report.
+func (mRetrieveConf *MRetrieveConf) NewMAcknowledgeInd(reportAllowed bool) *MAcknowledgeInd {
+	ack := &MAcknowledgeInd{
+		Type:          TYPE_ACKNOWLEDGE_IND,
+		UUID:          mRetrieveConf.UUID,
+		TransactionId: mRetrieveConf.TransactionId,
+		Version:       mRetrieveConf.Version,
+	}
+	if reportAllowed {
+		ack.ReportAllowed = REPORT_ALLOWED_YES
+	} else {
+		ack.ReportAllowed = REPORT_ALLOWED_NO
+	}
+	return ack
+}
+
 func NewMNotifyRespInd() *MNotifyRespInd {
 	return &MNotifyRespInd{Type: TYPE_NOTIFYRESP_IND}
 }
@@ -303,55 +656,241 @@ func NewMRetrieveConf(uuid string) *MRetrieveConf {
 	return &MRetrieveConf{Type: TYPE_RETRIEVE_CONF, UUID: uuid}
 }
 
-func genUUID() string {
-	var id string
-	random, err := os.Open("/dev/urandom")
+// negotiatedVersion returns the highest MMS version this package can use
+// with a peer that has advertised peerVersion, so that v1.3-only PDUs are
+// only emitted once the peer has confirmed it understands them.
+func negotiatedVersion(peerVersion byte) byte {
+	if peerVersion >= MMS_MESSAGE_VERSION_1_3 {
+		return MMS_MESSAGE_VERSION_1_3
+	}
+	return peerVersion
+}
+
+// NewMForwardReq builds a m-forward.req forwarding messageId to recipients.
+// peerVersion is the MMS version last advertised by the MMSC; the PDU is
+// only built when it is at least MMS_MESSAGE_VERSION_1_3.
+func NewMForwardReq(peerVersion byte, messageId string, recipients []string) (*MForwardReq, error) {
+	if negotiatedVersion(peerVersion) < MMS_MESSAGE_VERSION_1_3 {
+		return nil, fmt.Errorf("mms: m-forward.req requires peer version >= 1.3, got 0x%x", peerVersion)
+	}
+	uuid, err := newUUID()
 	if err != nil {
-		id = "1234567890ABCDEF"
-	} else {
-		defer random.Close()
-		b := make([]byte, 16)
-		random.Read(b)
-		id = fmt.Sprintf("%x", b)
+		return nil, fmt.Errorf("mms: generating transaction id: %w", err)
+	}
+	return &MForwardReq{
+		Type:          TYPE_FORWARD_REQ,
+		UUID:          uuid,
+		TransactionId: uuid,
+		Version:       MMS_MESSAGE_VERSION_1_3,
+		MessageId:     messageId,
+		To:            parseAddresses(recipients),
+	}, nil
+}
+
+// NewMCancelReq builds a m-cancel.req cancelling a previously submitted
+// message identified by cancelId. peerVersion is the MMS version last
+// advertised by the MMSC; the PDU is only built when it is at least
+// MMS_MESSAGE_VERSION_1_3.
+func NewMCancelReq(peerVersion byte, cancelId string) (*MCancelReq, error) {
+	if negotiatedVersion(peerVersion) < MMS_MESSAGE_VERSION_1_3 {
+		return nil, fmt.Errorf("mms: m-cancel.req requires peer version >= 1.3, got 0x%x", peerVersion)
+	}
+	uuid, err := newUUID()
+	if err != nil {
+		return nil, fmt.Errorf("mms: generating transaction id: %w", err)
+	}
+	return &MCancelReq{
+		Type:          TYPE_CANCEL_REQ,
+		UUID:          uuid,
+		TransactionId: uuid,
+		Version:       MMS_MESSAGE_VERSION_1_3,
+		CancelId:      cancelId,
+	}, nil
+}
+
+// RandSource is read by newUUID to generate transaction/message ids. It
+// defaults to crypto/rand.Reader; tests may swap it for a deterministic
+// source.
+var RandSource io.Reader = rand.Reader
+
+// newUUID returns a random RFC 4122 version-4 UUID string, reading entropy
+// from RandSource.
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(RandSource, b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// MMSErrorKind classifies a *MMSError as to whether the operation that
+// produced it is worth retrying.
+type MMSErrorKind int
+
+const (
+	KindOk MMSErrorKind = iota
+	KindTransient
+	KindPermanent
+)
+
+func (k MMSErrorKind) String() string {
+	switch k {
+	case KindOk:
+		return "ok"
+	case KindTransient:
+		return "transient"
+	case KindPermanent:
+		return "permanent"
+	default:
+		return "unknown"
 	}
-	return id
 }
 
+// MMSError is returned by (*MSendConf).Status and (*MRetrieveConf).Status
+// to let the transport layer decide whether a failed response/retrieve
+// status is worth retrying.
+type MMSError struct {
+	Code      byte
+	Kind      MMSErrorKind
+	Retryable bool
+	Text      string
+}
+
+func (e *MMSError) Error() string {
+	return fmt.Sprintf("mms: %s (status 0x%x): %s", e.Kind, e.Code, e.Text)
+}
+
+// Is allows errors.Is(err, ErrTransient) / errors.Is(err, ErrPermanent) to
+// keep working against the deprecated sentinels below.
+func (e *MMSError) Is(target error) bool {
+	switch target {
+	case ErrTransient:
+		return e.Kind == KindTransient
+	case ErrPermanent:
+		return e.Kind == KindPermanent
+	}
+	return false
+}
+
+// IsTransient reports whether err is a *MMSError worth retrying.
+func IsTransient(err error) bool {
+	var mmsErr *MMSError
+	return errors.As(err, &mmsErr) && mmsErr.Kind == KindTransient
+}
+
+// IsPermanent reports whether err is a *MMSError that should not be retried.
+func IsPermanent(err error) bool {
+	var mmsErr *MMSError
+	return errors.As(err, &mmsErr) && mmsErr.Kind == KindPermanent
+}
+
+// Deprecated: use IsTransient(err) instead.
 var ErrTransient = errors.New("Error-transient-failure")
+
+// Deprecated: use IsPermanent(err) instead.
 var ErrPermanent = errors.New("Error-permament-failure")
 
-func (mSendConf *MSendConf) Status() error {
-	s := mSendConf.ResponseStatus
-	// these are case by case Response Status and we need to determine each one
-	switch s {
-	case ResponseStatusOk:
-		return nil
-	case ResponseStatusErrorUnspecified:
-		return ErrTransient
-	case ResponseStatusErrorServiceDenied:
-		return ErrTransient
-	case ResponseStatusErrorMessageFormatCorrupt:
-		return ErrPermanent
-	case ResponseStatusErrorSendingAddressUnresolved:
-		return ErrPermanent
-	case ResponseStatusErrorMessageNotFound:
-		// this could be ErrTransient or ErrPermanent
-		return ErrPermanent
-	case ResponseStatusErrorNetworkProblem:
-		return ErrTransient
-	case ResponseStatusErrorContentNotAccepted:
-		return ErrPermanent
-	case ResponseStatusErrorUnsupportedMessage:
-		return ErrPermanent
+// responseStatusText carries the explicit Kind/text for Response Status
+// codes that need a specific human-readable message rather than the
+// generic per-range one. The obsolete 129-136 block is collapsed onto the
+// modern equivalent it corresponds to, per the Gecko/mms-engine refactor.
+var responseStatusText = map[byte]struct {
+	Kind MMSErrorKind
+	Text string
+}{
+	ResponseStatusOk:                                            {KindOk, "ok"},
+	ResponseStatusErrorUnspecified:                              {KindTransient, "unspecified error"},
+	ResponseStatusErrorServiceDenied:                            {KindPermanent, "service denied"},
+	ResponseStatusErrorMessageFormatCorrupt:                     {KindPermanent, "message format corrupt"},
+	ResponseStatusErrorSendingAddressUnresolved:                 {KindPermanent, "address unresolved"},
+	ResponseStatusErrorMessageNotFound:                          {KindPermanent, "message not found"},
+	ResponseStatusErrorNetworkProblem:                           {KindTransient, "network problem"},
+	ResponseStatusErrorContentNotAccepted:                       {KindPermanent, "content not accepted"},
+	ResponseStatusErrorUnsupportedMessage:                       {KindPermanent, "unsupported message"},
+	ResponseStatusErrorTransientFailure:                         {KindTransient, "transient failure"},
+	ResponseStatusErrorTransientAddressUnresolved:               {KindTransient, "address unresolved"},
+	ResponseStatusErrorTransientMessageNotFound:                 {KindTransient, "message not found"},
+	ResponseStatusErrorTransientNetworkProblem:                  {KindTransient, "network problem"},
+	ResponseStatusErrorPermanentFailure:                         {KindPermanent, "permanent failure"},
+	ResponseStatusErrorPermanentServiceDenied:                   {KindPermanent, "service denied"},
+	ResponseStatusErrorPermanentMessageFormatCorrupt:            {KindPermanent, "message format corrupt"},
+	ResponseStatusErrorPermanentAddressUnresolved:               {KindPermanent, "address unresolved"},
+	ResponseStatusErrorPermanentMessageNotFound:                 {KindPermanent, "message not found"},
+	ResponseStatusErrorPermanentContentNotAccepted:              {KindPermanent, "content not accepted"},
+	ResponseStatusErrorPermanentReplyChargingLimitationsNotMet:  {KindPermanent, "reply charging limitations not met"},
+	ResponseStatusErrorPermanentReplyChargingRequestNotAccepted: {KindPermanent, "reply charging request not accepted"},
+	ResponseStatusErrorPermanentReplyChargingForwardingDenied:   {KindPermanent, "reply charging forwarding denied"},
+	ResponseStatusErrorPermanentReplyChargingNotSupported:       {KindPermanent, "reply charging not supported"},
+}
+
+// classifyResponseStatus turns a X-Mms-Response-Status byte into a
+// *MMSError, following the range rule in OMA-WAP-MMS section 7.2.27: any
+// unlisted value in [192, 224) is transient, and any unlisted value in
+// [224, 256) is permanent.
+func classifyResponseStatus(s byte) *MMSError {
+	if entry, ok := responseStatusText[s]; ok {
+		if entry.Kind == KindOk {
+			return nil
+		}
+		return &MMSError{Code: s, Kind: entry.Kind, Retryable: entry.Kind == KindTransient, Text: entry.Text}
+	}
+	if s >= ResponseStatusErrorTransientFailure && s < ResponseStatusErrorPermanentFailure {
+		return &MMSError{Code: s, Kind: KindTransient, Retryable: true, Text: "transient failure"}
 	}
+	return &MMSError{Code: s, Kind: KindPermanent, Retryable: false, Text: "permanent failure"}
+}
+
+// retrieveStatusText gives the X-Mms-Retrieve-Status field used by
+// m-retrieve.conf its own labels: its codes share Response Status's byte
+// ranges (192-194 transient, 224-227 permanent) but not its meanings.
+var retrieveStatusText = map[byte]struct {
+	Kind MMSErrorKind
+	Text string
+}{
+	RetrieveStatusOk:                               {KindOk, "ok"},
+	RetrieveStatusErrorTransientFailure:            {KindTransient, "transient failure"},
+	RetrieveStatusErrorTransientMessageNotFound:    {KindTransient, "message not found"},
+	RetrieveStatusErrorTransientNetworkProblem:     {KindTransient, "network problem"},
+	RetrieveStatusErrorPermanentFailure:            {KindPermanent, "permanent failure"},
+	RetrieveStatusErrorPermanentServiceDenied:      {KindPermanent, "service denied"},
+	RetrieveStatusErrorPermanentMessageNotFound:    {KindPermanent, "message not found"},
+	RetrieveStatusErrorPermanentContentNotAccepted: {KindPermanent, "content unsupported"},
+}
+
+// classifyRetrieveStatus turns a X-Mms-Retrieve-Status byte into a
+// *MMSError. Codes 195-223 and 228-255 are bucketed per-spec onto the
+// transient/permanent range they fall in.
+func classifyRetrieveStatus(s byte) *MMSError {
+	if entry, ok := retrieveStatusText[s]; ok {
+		if entry.Kind == KindOk {
+			return nil
+		}
+		return &MMSError{Code: s, Kind: entry.Kind, Retryable: entry.Kind == KindTransient, Text: entry.Text}
+	}
+	if s >= RetrieveStatusErrorTransientFailure && s < RetrieveStatusErrorPermanentFailure {
+		return &MMSError{Code: s, Kind: KindTransient, Retryable: true, Text: "transient failure"}
+	}
+	return &MMSError{Code: s, Kind: KindPermanent, Retryable: false, Text: "permanent failure"}
+}
 
-	// these are the Response Status we can group
-	if s >= ResponseStatusErrorTransientFailure && s <= ResponseStatusErrorTransientMaxReserved {
-		return ErrTransient
-	} else if s >= ResponseStatusErrorPermanentFailure && s <= ResponseStatusErrorPermamentMaxReserved {
-		return ErrPermanent
+// Status classifies the X-Mms-Response-Status of this m-send.conf, returning
+// nil on success or a *MMSError describing whether the send is worth
+// retrying.
+func (mSendConf *MSendConf) Status() error {
+	if err := classifyResponseStatus(mSendConf.ResponseStatus); err != nil {
+		return err
 	}
+	return nil
+}
 
-	// any case not handled is a permanent error
-	return ErrPermanent
+// Status classifies the X-Mms-Retrieve-Status of this m-retrieve.conf,
+// returning nil on success or a *MMSError describing whether the retrieve
+// is worth retrying.
+func (mRetrieveConf *MRetrieveConf) Status() error {
+	if err := classifyRetrieveStatus(mRetrieveConf.RetrieveStatus); err != nil {
+		return err
+	}
+	return nil
 }
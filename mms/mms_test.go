@@ -0,0 +1,94 @@
+/*
+ * Copyright 2014 Canonical Ltd.
+ *
+ * Authors:
+ * Sergio Schvezov: sergio.schvezov@cannical.com
+ *
+ * This file is part of mms.
+ *
+ * mms is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; version 3.
+ *
+ * mms is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mms
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestNewUUIDDeterministic confirms RandSource can be swapped for a
+// deterministic reader, as RandSource's doc comment promises.
+func TestNewUUIDDeterministic(t *testing.T) {
+	old := RandSource
+	defer func() { RandSource = old }()
+
+	RandSource = bytes.NewReader(bytes.Repeat([]byte{0x42}, 16))
+	got, err := newUUID()
+	if err != nil {
+		t.Fatalf("newUUID: %v", err)
+	}
+	want := "42424242-4242-4242-8242-424242424242"
+	if got != want {
+		t.Errorf("newUUID() = %q, want %q", got, want)
+	}
+}
+
+// TestNewUUIDError confirms a RandSource failure is surfaced as an error
+// rather than swallowed into an empty id.
+func TestNewUUIDError(t *testing.T) {
+	old := RandSource
+	defer func() { RandSource = old }()
+
+	wantErr := errors.New("boom")
+	RandSource = errReader{wantErr}
+	if _, err := newUUID(); err != wantErr {
+		t.Errorf("newUUID() error = %v, want %v", err, wantErr)
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+// TestClassifyRetrieveStatus confirms the X-Mms-Retrieve-Status classifier
+// uses its own labels rather than Response Status's: code 193 means
+// message-not-found here, not address-unresolved.
+func TestClassifyRetrieveStatus(t *testing.T) {
+	cases := []struct {
+		status        byte
+		wantErr       bool
+		wantRetryable bool
+		wantText      string
+	}{
+		{RetrieveStatusOk, false, false, ""},
+		{RetrieveStatusErrorTransientMessageNotFound, true, true, "message not found"},
+		{RetrieveStatusErrorPermanentServiceDenied, true, false, "service denied"},
+	}
+	for _, c := range cases {
+		mmsErr := classifyRetrieveStatus(c.status)
+		if c.wantErr != (mmsErr != nil) {
+			t.Errorf("classifyRetrieveStatus(%d) = %v, wantErr %v", c.status, mmsErr, c.wantErr)
+			continue
+		}
+		if mmsErr == nil {
+			continue
+		}
+		if mmsErr.Retryable != c.wantRetryable {
+			t.Errorf("classifyRetrieveStatus(%d).Retryable = %v, want %v", c.status, mmsErr.Retryable, c.wantRetryable)
+		}
+		if mmsErr.Text != c.wantText {
+			t.Errorf("classifyRetrieveStatus(%d).Text = %q, want %q", c.status, mmsErr.Text, c.wantText)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2014 Canonical Ltd.
+ *
+ * Authors:
+ * Sergio Schvezov: sergio.schvezov@cannical.com
+ *
+ * This file is part of mms.
+ *
+ * mms is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; version 3.
+ *
+ * mms is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package txn
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotFound is returned by Store.Get when no Transaction is persisted for
+// a given transaction id.
+var ErrNotFound = errors.New("txn: transaction not found")
+
+// Store persists Transaction state across process restarts so that Engine
+// can resume a deferred or in-flight transaction. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	Get(transactionId string) (*Transaction, error)
+	Put(txn *Transaction) error
+	Delete(transactionId string) error
+	List() ([]*Transaction, error)
+}
+
+// FileStore is the default Store, persisting one JSON file per transaction
+// in a directory. It is intentionally simple so it has no dependencies
+// beyond the standard library; callers wanting a single-file embedded
+// database can implement Store against BoltDB instead.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore persisting transactions under dir,
+// creating it if necessary.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// path maps transactionId to a file under s.dir. transactionId comes off
+// the wire (X-Mms-Transaction-Id), so it is hashed rather than used
+// directly as a filename: a garbled or hostile id containing "/" or ".."
+// must not be able to escape s.dir.
+func (s *FileStore) path(transactionId string) string {
+	sum := sha256.Sum256([]byte(transactionId))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (s *FileStore) Get(transactionId string) (*Transaction, error) {
+	data, err := os.ReadFile(s.path(transactionId))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var txn Transaction
+	if err := json.Unmarshal(data, &txn); err != nil {
+		return nil, err
+	}
+	return &txn, nil
+}
+
+func (s *FileStore) Put(txn *Transaction) error {
+	data, err := json.Marshal(txn)
+	if err != nil {
+		return err
+	}
+	// Each Put writes its own temp file, so concurrent Puts of the same
+	// transaction cannot interleave their bytes before the rename.
+	tmp, err := os.CreateTemp(s.dir, "put-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(txn.TransactionId))
+}
+
+func (s *FileStore) Delete(transactionId string) error {
+	err := os.Remove(s.path(transactionId))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *FileStore) List() ([]*Transaction, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	txns := make([]*Transaction, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var txn Transaction
+		if err := json.Unmarshal(data, &txn); err != nil {
+			return nil, err
+		}
+		txns = append(txns, &txn)
+	}
+	return txns, nil
+}
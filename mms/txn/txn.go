@@ -0,0 +1,296 @@
+/*
+ * Copyright 2014 Canonical Ltd.
+ *
+ * Authors:
+ * Sergio Schvezov: sergio.schvezov@cannical.com
+ *
+ * This file is part of mms.
+ *
+ * mms is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; version 3.
+ *
+ * mms is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package txn turns the mms package's codec into a usable MMS client state
+// machine: it persists the lifecycle of a transaction from the initial
+// m-notification.ind through to acknowledgement, and drives retries off the
+// response/retrieve status classification in the mms package.
+package txn
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/doflah/nuntium/mms"
+)
+
+// State is the lifecycle stage of a Transaction.
+type State int
+
+const (
+	StateNotified State = iota
+	StateDeferred
+	StateRetrieving
+	StateRetrieved
+	StateAcknowledged
+	StateSent
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateNotified:
+		return "notified"
+	case StateDeferred:
+		return "deferred"
+	case StateRetrieving:
+		return "retrieving"
+	case StateRetrieved:
+		return "retrieved"
+	case StateAcknowledged:
+		return "acknowledged"
+	case StateSent:
+		return "sent"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Transaction is the persisted state of a single MMS transaction, keyed by
+// the X-Mms-Transaction-Id shared by every PDU in its lifecycle.
+type Transaction struct {
+	TransactionId string
+	UUID          string
+	State         State
+	Attempts      int
+	NextRetry     time.Time
+	Expiry        time.Time
+
+	// DeliveryTime is the X-Mms-Delivery-Time requested for a SendReq this
+	// transaction submits: retries are never scheduled before it.
+	DeliveryTime time.Time
+
+	SendReq      *mms.MSendReq
+	Notification *mms.MNotificationInd
+	SendConf     *mms.MSendConf
+	RetrieveConf *mms.MRetrieveConf
+	Acknowledge  *mms.MAcknowledgeInd
+}
+
+// EventKind distinguishes the events emitted by an Engine on its Events
+// channel.
+type EventKind int
+
+const (
+	EventStateChanged EventKind = iota
+	EventRetryScheduled
+	EventFailed
+)
+
+// Event reports a Transaction's state transition to the Engine's caller.
+type Event struct {
+	Kind          EventKind
+	TransactionId string
+	State         State
+	Err           error
+}
+
+// RetryPolicy computes how long to wait before retrying a transaction that
+// failed transiently, given the number of attempts made so far (1 for the
+// first failure).
+type RetryPolicy func(attempt int) time.Duration
+
+// DefaultRetryPolicy doubles the delay on each attempt, starting at 30
+// seconds and capping at 30 minutes.
+func DefaultRetryPolicy(attempt int) time.Duration {
+	delay := 30 * time.Second
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= 30*time.Minute {
+			return 30 * time.Minute
+		}
+	}
+	return delay
+}
+
+// Engine drives Transaction state transitions from incoming PDUs and
+// persists them to a Store.
+type Engine struct {
+	Store       Store
+	RetryPolicy RetryPolicy
+	Events      chan Event
+
+	maxAttempts int
+}
+
+// NewEngine returns an Engine persisting to store and emitting events on a
+// buffered channel of the given size. maxAttempts bounds how many times a
+// transient failure is retried before the transaction is marked Failed.
+func NewEngine(store Store, maxAttempts int, eventBuffer int) *Engine {
+	return &Engine{
+		Store:       store,
+		RetryPolicy: DefaultRetryPolicy,
+		Events:      make(chan Event, eventBuffer),
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Handle advances the transaction keyed by pdu's TransactionId according to
+// pdu's concrete type, persists the result, and emits an Event describing
+// the transition. The Event is sent without blocking and is dropped if the
+// Events channel's buffer is full.
+func (e *Engine) Handle(pdu interface{}) error {
+	switch p := pdu.(type) {
+	case *mms.MNotificationInd:
+		return e.handleNotification(p)
+	case *mms.MSendConf:
+		return e.handleSendConf(p)
+	case *mms.MRetrieveConf:
+		return e.handleRetrieveConf(p)
+	case *mms.MAcknowledgeInd:
+		return e.handleAcknowledge(p)
+	default:
+		return fmt.Errorf("txn: unsupported PDU type %T", pdu)
+	}
+}
+
+func (e *Engine) handleNotification(ind *mms.MNotificationInd) error {
+	txn := &Transaction{
+		TransactionId: ind.TransactionId,
+		UUID:          ind.UUID,
+		State:         StateNotified,
+		Notification:  ind,
+	}
+	if ind.Expiry > 0 {
+		txn.Expiry = time.Unix(int64(ind.Expiry), 0)
+	}
+	return e.save(txn, EventStateChanged, nil)
+}
+
+// BeginSend records the X-Mms-Expiry and X-Mms-Delivery-Time requested by an
+// outgoing SendReq, to be called by the caller before submitting it.
+// applyStatus consults both once the matching MSendConf arrives.
+func (e *Engine) BeginSend(req *mms.MSendReq) error {
+	txn, err := e.loadOrNew(req.TransactionId)
+	if err != nil {
+		return err
+	}
+	txn.SendReq = req
+	if req.Expiry > 0 {
+		txn.Expiry = time.Unix(int64(req.Expiry), 0)
+	}
+	if req.DeliveryTime > 0 {
+		txn.DeliveryTime = time.Unix(int64(req.DeliveryTime), 0)
+	}
+	return e.save(txn, EventStateChanged, nil)
+}
+
+func (e *Engine) handleSendConf(conf *mms.MSendConf) error {
+	txn, err := e.loadOrNew(conf.TransactionId)
+	if err != nil {
+		return err
+	}
+	txn.SendConf = conf
+	return e.applyStatus(txn, conf.Status(), StateSent)
+}
+
+// BeginRetrieval marks the transaction keyed by transactionId as
+// in-progress, to be called by the caller once it starts fetching the
+// message content from the MMSC's X-Mms-Content-Location. handleRetrieveConf
+// moves it on to StateRetrieved once that fetch completes.
+func (e *Engine) BeginRetrieval(transactionId string) error {
+	txn, err := e.loadOrNew(transactionId)
+	if err != nil {
+		return err
+	}
+	txn.State = StateRetrieving
+	return e.save(txn, EventStateChanged, nil)
+}
+
+func (e *Engine) handleRetrieveConf(conf *mms.MRetrieveConf) error {
+	txn, err := e.loadOrNew(conf.TransactionId)
+	if err != nil {
+		return err
+	}
+	txn.RetrieveConf = conf
+	return e.applyStatus(txn, conf.Status(), StateRetrieved)
+}
+
+func (e *Engine) handleAcknowledge(ack *mms.MAcknowledgeInd) error {
+	txn, err := e.loadOrNew(ack.TransactionId)
+	if err != nil {
+		return err
+	}
+	txn.Acknowledge = ack
+	txn.State = StateAcknowledged
+	return e.save(txn, EventStateChanged, nil)
+}
+
+func (e *Engine) applyStatus(txn *Transaction, err error, successState State) error {
+	switch {
+	case err == nil:
+		txn.State = successState
+		return e.save(txn, EventStateChanged, nil)
+	case mms.IsTransient(err) && !e.expired(txn):
+		txn.Attempts++
+		if txn.Attempts > e.maxAttempts {
+			txn.State = StateFailed
+			return e.save(txn, EventFailed, err)
+		}
+		txn.State = StateDeferred
+		txn.NextRetry = e.nextRetry(txn)
+		return e.save(txn, EventRetryScheduled, err)
+	default:
+		txn.State = StateFailed
+		return e.save(txn, EventFailed, err)
+	}
+}
+
+// expired reports whether txn's X-Mms-Expiry has already passed, in which
+// case a transient failure is no longer worth retrying.
+func (e *Engine) expired(txn *Transaction) bool {
+	return !txn.Expiry.IsZero() && time.Now().After(txn.Expiry)
+}
+
+// nextRetry computes when to retry txn, never before the X-Mms-Delivery-Time
+// requested for its SendReq, if any.
+func (e *Engine) nextRetry(txn *Transaction) time.Time {
+	next := time.Now().Add(e.RetryPolicy(txn.Attempts))
+	if !txn.DeliveryTime.IsZero() && txn.DeliveryTime.After(next) {
+		return txn.DeliveryTime
+	}
+	return next
+}
+
+func (e *Engine) loadOrNew(transactionId string) (*Transaction, error) {
+	txn, err := e.Store.Get(transactionId)
+	if err == nil {
+		return txn, nil
+	}
+	if err == ErrNotFound {
+		return &Transaction{TransactionId: transactionId}, nil
+	}
+	return nil, err
+}
+
+func (e *Engine) save(txn *Transaction, kind EventKind, cause error) error {
+	if err := e.Store.Put(txn); err != nil {
+		return err
+	}
+	event := Event{Kind: kind, TransactionId: txn.TransactionId, State: txn.State, Err: cause}
+	select {
+	case e.Events <- event:
+	default:
+	}
+	return nil
+}